@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/tsuru/rpaas-operator/pkg/rpaas/client"
+)
+
+// NewCmdPortForward builds the "rpaasv2 port-forward" command. All flag
+// parsing lives here so that pkg/rpaas/client stays usable as a plain
+// library: it only ever sees the resulting client.PortForwardOptions.
+func NewCmdPortForward() *cobra.Command {
+	var opts client.PortForwardOptions
+	var labels map[string]string
+	var service, deployment, statefulSet string
+
+	cmd := &cobra.Command{
+		Use:   "port-forward -a <service>/<instance> [local:remote]...",
+		Short: "Forward local ports to a pod, Service, Deployment or StatefulSet",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.LabelSelector = labels
+
+			target, err := targetFromFlags(opts.Pod, service, deployment, statefulSet)
+			if err != nil {
+				return err
+			}
+			opts.Target = target
+
+			if len(args) > 0 {
+				mappings, err := client.ParsePortMappings(strings.Join(args, ","))
+				if err != nil {
+					return errors.Wrap(err, "invalid port mapping")
+				}
+				opts.Mappings = mappings
+			}
+
+			ctx, cancel := context.WithCancel(cmd.Context())
+			defer cancel()
+
+			opts.Stdout = os.Stdout
+			opts.Stderr = os.Stderr
+
+			rpaasClient, err := client.NewClientThroughConfig(cmd.Flags())
+			if err != nil {
+				return err
+			}
+
+			_, err = rpaasClient.StartPortForward(ctx, opts)
+			return err
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&opts.Pod, "pod", "", "pod name to forward to")
+	flags.StringToStringVar(&labels, "label", nil, "label selector (key=value), used when --pod is not set")
+	flags.StringVar(&service, "service", "", "Service name to resolve a target pod from, translating the Service port to its targetPort")
+	flags.StringVar(&deployment, "deployment", "", "Deployment name to resolve a target pod from")
+	flags.StringVar(&statefulSet, "statefulset", "", "StatefulSet name to resolve a target pod from")
+	flags.StringVar(&opts.Namespace, "namespace", "", "namespace to look for the target in")
+	flags.IntVar(&opts.LocalPort, "listen", 0, "local port to bind, defaults to a random free port")
+	flags.IntVar(&opts.RemotePort, "port", 0, "remote port to forward to, used when no [local:remote]... mappings are given")
+	flags.StringVar(&opts.Container, "container", "", "container to forward into, defaults to the nginx container")
+	flags.DurationVar(&opts.Timeout, "timeout", 0, "how long to keep the tunnel open, defaults to running until interrupted")
+	flags.IntVar(&opts.MaxRetries, "max-retries", 0, "how many times to reconnect a pod after its tunnel drops before giving up on it, defaults to 5")
+	flags.DurationVar(&opts.MaxBackoff, "max-backoff", 0, "cap on the exponential backoff between reconnect attempts, defaults to 30s")
+
+	return cmd
+}
+
+// targetFromFlags builds a client.TargetRef from the mutually exclusive
+// --pod/--service/--deployment/--statefulset flags. At most one of
+// --service/--deployment/--statefulset may be set, and none of them may be
+// combined with --pod.
+func targetFromFlags(pod, service, deployment, statefulSet string) (client.TargetRef, error) {
+	set := map[client.TargetKind]string{
+		client.TargetKindService:     service,
+		client.TargetKindDeployment:  deployment,
+		client.TargetKindStatefulSet: statefulSet,
+	}
+
+	var kind client.TargetKind
+	var name string
+	for k, v := range set {
+		if v == "" {
+			continue
+		}
+		if kind != "" {
+			return client.TargetRef{}, errors.New("only one of --service, --deployment or --statefulset may be set")
+		}
+		kind, name = k, v
+	}
+
+	if kind == "" {
+		return client.TargetRef{}, nil
+	}
+	if pod != "" {
+		return client.TargetRef{}, errors.New("--pod cannot be combined with --service, --deployment or --statefulset")
+	}
+
+	return client.TargetRef{Kind: kind, Name: name}, nil
+}
@@ -2,13 +2,15 @@ package client
 
 import (
 	"context"
-	"flag"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"net"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -17,6 +19,8 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/portforward"
@@ -25,18 +29,199 @@ import (
 	sigsk8sconfig "sigs.k8s.io/controller-runtime/pkg/client/config"
 )
 
-type labelsFlags map[string]string
+// defaultContainer is used when neither PortForwardOptions.Container nor
+// PortForward.Container is set, matching the previous hardcoded behavior.
+const defaultContainer = "nginx"
 
-func (l *labelsFlags) String() string {
-	return fmt.Sprintf("%v", *l)
+// PortMapping describes a single local:remote port pair to forward, e.g.
+// the "80:8080" half of "80:8080,443:8443".
+type PortMapping struct {
+	ListenPort      int
+	DestinationPort int
 }
-func (l *labelsFlags) Set(value string) error {
-	label := strings.SplitN(value, "=", 2)
-	if len(label) != 2 {
-		return errors.New("labels must include equal sign")
+
+// ParsePortMappings parses a comma-separated list of "local:remote" (or bare
+// "port", meaning local == remote) pairs into a slice of PortMapping.
+func ParsePortMappings(raw string) ([]PortMapping, error) {
+	var mappings []PortMapping
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		var listenPort, destinationPort int
+		if idx := strings.IndexByte(part, ':'); idx >= 0 {
+			var err error
+			listenPort, err = strconv.Atoi(part[:idx])
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid listen port in %q", part)
+			}
+			destinationPort, err = strconv.Atoi(part[idx+1:])
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid destination port in %q", part)
+			}
+		} else {
+			port, err := strconv.Atoi(part)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid port %q", part)
+			}
+			listenPort, destinationPort = port, port
+		}
+
+		mappings = append(mappings, PortMapping{ListenPort: listenPort, DestinationPort: destinationPort})
 	}
-	(*l)[label[0]] = label[1]
-	return nil
+
+	if len(mappings) == 0 {
+		return nil, errors.New("no port mappings specified")
+	}
+
+	return mappings, nil
+}
+
+// PodAddress reports where a single forwarded pod can be reached locally.
+type PodAddress struct {
+	Pod      string
+	Mappings []PortMapping
+}
+
+// podForward tracks the lifecycle of the forwarder running for a single pod.
+type podForward struct {
+	pod       string
+	mappings  []PortMapping
+	stopChan  chan struct{}
+	readyChan chan struct{}
+}
+
+// Action tells the supervisor loop how to react to a forwarder exiting with
+// an error.
+type Action int
+
+const (
+	// Retry rebuilds the dialer and restarts the tunnel after a backoff.
+	Retry Action = iota
+	// Abort gives up on the pod; it is removed from Addresses()/Ready().
+	Abort
+	// Ignore retries immediately, without counting against MaxRetries or
+	// growing the backoff. Useful for errors known to be transient.
+	Ignore
+)
+
+// ErrorHandler decides what to do when a forwarder exits with an error,
+// e.g. because the API server dropped the SPDY stream or the pod restarted.
+type ErrorHandler func(error) Action
+
+const (
+	defaultMaxRetries  = 5
+	defaultInitBackoff = time.Second
+	defaultMaxBackoff  = 30 * time.Second
+)
+
+// TargetKind identifies the kind of resource a TargetRef points at.
+type TargetKind string
+
+const (
+	TargetKindPod         TargetKind = "Pod"
+	TargetKindService     TargetKind = "Service"
+	TargetKindDeployment  TargetKind = "Deployment"
+	TargetKindStatefulSet TargetKind = "StatefulSet"
+)
+
+// TargetRef names the resource a PortForward should forward to. For
+// TargetKindPod, Name is the pod name. For the other kinds, it is resolved
+// to the matching Ready pods: a Service's selector, or a Deployment's /
+// StatefulSet's .spec.selector.
+type TargetRef struct {
+	Kind      TargetKind
+	Name      string
+	Namespace string
+}
+
+// resolveTarget turns p.Target into p.Labels (or p.Name, for a Pod target)
+// plus, for a Service target, the targetPort corresponding to
+// p.DestinationPort. It is a no-op when Target.Kind is unset.
+func (p *PortForward) resolveTarget(ctx context.Context) error {
+	if p.Target.Kind == "" {
+		return nil
+	}
+
+	namespace := p.Target.Namespace
+	if namespace == "" {
+		namespace = p.Namespace
+	}
+	p.Namespace = namespace
+	// Target takes precedence over Name: only a Pod target sets it below.
+	p.Name = ""
+
+	switch p.Target.Kind {
+	case TargetKindPod:
+		p.Name = p.Target.Name
+		return nil
+
+	case TargetKindService:
+		svc, err := p.Clientset.CoreV1().Services(namespace).Get(ctx, p.Target.Name, metav1.GetOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "Could not get service %q", p.Target.Name)
+		}
+		if len(svc.Spec.Selector) == 0 {
+			return errors.Errorf("service %q has no selector", p.Target.Name)
+		}
+		p.Labels = metav1.LabelSelector{MatchLabels: svc.Spec.Selector}
+		p.DestinationPort = resolveServiceTargetPort(svc, p.DestinationPort)
+		for i, m := range p.Mappings {
+			p.Mappings[i].DestinationPort = resolveServiceTargetPort(svc, m.DestinationPort)
+		}
+		return nil
+
+	case TargetKindDeployment:
+		dep, err := p.Clientset.AppsV1().Deployments(namespace).Get(ctx, p.Target.Name, metav1.GetOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "Could not get deployment %q", p.Target.Name)
+		}
+		if dep.Spec.Selector == nil {
+			return errors.Errorf("deployment %q has no selector", p.Target.Name)
+		}
+		p.Labels = *dep.Spec.Selector
+		return nil
+
+	case TargetKindStatefulSet:
+		sts, err := p.Clientset.AppsV1().StatefulSets(namespace).Get(ctx, p.Target.Name, metav1.GetOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "Could not get statefulset %q", p.Target.Name)
+		}
+		if sts.Spec.Selector == nil {
+			return errors.Errorf("statefulset %q has no selector", p.Target.Name)
+		}
+		p.Labels = *sts.Spec.Selector
+		return nil
+
+	default:
+		return errors.Errorf("unsupported target kind %q", p.Target.Kind)
+	}
+}
+
+// resolveServiceTargetPort translates a Service port number to the pod
+// targetPort it maps to, e.g. so "rpaasv2 port-forward --service
+// my-instance-nginx 8080:80" forwards to the container port backing the
+// Service's port 80. If no matching port is found, servicePort is returned
+// unchanged so numeric targetPorts and direct container ports keep working.
+func resolveServiceTargetPort(svc *v1.Service, servicePort int) int {
+	for _, port := range svc.Spec.Ports {
+		if int(port.Port) != servicePort {
+			continue
+		}
+		if port.TargetPort.Type == intstr.Int {
+			if port.TargetPort.IntValue() != 0 {
+				return port.TargetPort.IntValue()
+			}
+			return servicePort
+		}
+		// A named targetPort can only be resolved against a specific pod's
+		// container ports, which the caller does not have here; fall back
+		// to the service port itself.
+		return servicePort
+	}
+	return servicePort
 }
 
 type PortForward struct {
@@ -49,6 +234,43 @@ type PortForward struct {
 	Namespace       string
 	StopChan        chan struct{}
 	ReadyChan       chan struct{}
+
+	// Mappings lists the ports to forward for every matched pod. When empty,
+	// it falls back to the single DestinationPort/ListenPort pair above.
+	Mappings []PortMapping
+
+	// ErrorHandler, when set, is consulted whenever a forwarder exits with an
+	// error so callers can observe dropped SPDY streams, API-server
+	// disconnects or pod restarts. Nil means Abort.
+	ErrorHandler ErrorHandler
+	// MaxRetries bounds how many times a pod is reconnected after an error
+	// before it is given up on. Zero means defaultMaxRetries.
+	MaxRetries int
+	// MaxBackoff caps the exponential backoff between reconnect attempts.
+	// Zero means defaultMaxBackoff.
+	MaxBackoff time.Duration
+
+	// Target, when set, resolves Name or Labels from a Service, Deployment
+	// or StatefulSet instead of requiring the caller to know the pod
+	// selector up front. Takes precedence over Name/Labels.
+	Target TargetRef
+
+	// Container is the container within the target pod to forward into.
+	// Defaults to defaultContainer when empty.
+	Container string
+	// Stdout and Stderr receive the forwarder's logs. Default to
+	// ioutil.Discard when nil.
+	Stdout io.Writer
+	Stderr io.Writer
+
+	mu            sync.Mutex
+	forwardedPods map[string]*podForward
+	rrIndex       int
+
+	// stopped is closed by Stop(), guarded by stopOnce so a direct Stop()
+	// call and ctx cancellation racing each other don't double-close it.
+	stopped  chan struct{}
+	stopOnce sync.Once
 }
 
 func NewPortForwarder(name string, labels metav1.LabelSelector, port int, namespace string) (*PortForward, error) {
@@ -56,6 +278,7 @@ func NewPortForwarder(name string, labels metav1.LabelSelector, port int, namesp
 		Name:            name,
 		DestinationPort: port,
 		Namespace:       namespace,
+		forwardedPods:   make(map[string]*podForward),
 	}
 
 	var err error
@@ -74,46 +297,436 @@ func NewPortForwarder(name string, labels metav1.LabelSelector, port int, namesp
 	return pf, nil
 }
 
-// Start a port forward to a pod - blocks until the tunnel is ready for use.
+// portMappings returns the configured Mappings, falling back to the single
+// DestinationPort/ListenPort pair for backwards compatibility.
+func (p *PortForward) portMappings() ([]PortMapping, error) {
+	if len(p.Mappings) > 0 {
+		return p.Mappings, nil
+	}
+
+	listenPort, err := p.getListenPort()
+	if err != nil {
+		return nil, errors.Wrap(err, "Could not find a port to bind to")
+	}
+
+	return []PortMapping{{ListenPort: listenPort, DestinationPort: p.DestinationPort}}, nil
+}
+
+// Start a port forward - blocks until at least one tunnel is ready for use.
+//
+// When Name is set, a single pod is forwarded, as before. When Labels is set
+// instead, Start watches the namespace for pods matching Labels and forwards
+// every Ready replica, tearing down the tunnel for pods that are deleted or
+// stop being Ready and opening new tunnels for newly Ready replicas. When
+// Target is set, it is resolved to Name/Labels (and, for a Service target,
+// DestinationPort) first.
+//
+// Start honors ctx for its whole lifetime, not just while resolving the
+// target: canceling ctx (a Ctrl-C or a deadline) tears down every tunnel the
+// same way an explicit Stop() would.
 func (p *PortForward) Start(ctx context.Context) error {
 	p.StopChan = make(chan struct{}, 1)
-	readyChan := make(chan struct{}, 1)
-	errChan := make(chan error, 1)
+	if p.ReadyChan == nil {
+		p.ReadyChan = make(chan struct{}, 1)
+	}
 
-	listenPort, err := p.getListenPort()
+	if p.forwardedPods == nil {
+		p.forwardedPods = make(map[string]*podForward)
+	}
+
+	p.stopped = make(chan struct{})
+	p.stopOnce = sync.Once{}
+
+	if err := p.resolveTarget(ctx); err != nil {
+		return errors.Wrap(err, "Could not resolve port forward target")
+	}
+
+	mappings, err := p.portMappings()
 	if err != nil {
-		return errors.Wrap(err, "Could not find a port to bind to")
+		return err
 	}
-	dialer, err := p.dialer(ctx)
+
+	if p.Name != "" {
+		if _, err := p.startPod(ctx, p.Name, mappings); err != nil {
+			return err
+		}
+		close(p.ReadyChan)
+		go p.stopOnDone(ctx)
+		return nil
+	}
+
+	pods, err := p.findPodsByLabels(ctx)
 	if err != nil {
-		return errors.Wrap(err, "Could not create a dialer")
+		return errors.Wrap(err, "Could not list pods for label selector")
+	}
+
+	if len(pods) > 1 {
+		for _, m := range mappings {
+			if m.ListenPort != 0 {
+				return errors.Errorf("cannot forward a fixed listen port (%d) to %d matched pods: only one pod can bind it, specify a port of 0 to auto-assign a listen port per pod", m.ListenPort, len(pods))
+			}
+		}
+	}
+
+	for _, pod := range pods {
+		if _, err := p.startPod(ctx, pod, mappings); err != nil {
+			// Tear down whatever earlier pods in this loop already opened
+			// a tunnel to, rather than leaving them running with nothing
+			// left to stop them.
+			p.Stop()
+			return errors.Wrapf(err, "Could not start port forward for pod %q", pod)
+		}
+	}
+
+	go p.reconcile(ctx, mappings)
+
+	close(p.ReadyChan)
+	go p.stopOnDone(ctx)
+	return nil
+}
+
+// stopOnDone calls Stop() once ctx is done, so canceling the context a
+// caller passed to Start tears every tunnel down the same way an explicit
+// Stop() would. It returns early, without calling Stop() again, if Stop()
+// was already called directly while ctx was still alive. It is only started
+// once Start has succeeded, so a failed Start never leaks it.
+func (p *PortForward) stopOnDone(ctx context.Context) {
+	select {
+	case <-ctx.Done():
+		p.Stop()
+	case <-p.stopped:
 	}
+}
 
-	ports := []string{
-		fmt.Sprintf("%d:%d", listenPort, 80),
+// startPod opens the tunnels described by mappings to pod and tracks it in
+// forwardedPods, similar to how Skaffold's PortForwarder tracks a
+// forwardedPods map keyed by pod identity. Calling it again for a pod that is
+// already forwarded is a no-op. Once the first tunnel is up, a supervisor
+// goroutine takes over reconnecting the pod if its forwarder later exits
+// with an error.
+func (p *PortForward) startPod(ctx context.Context, pod string, mappings []PortMapping) (*podForward, error) {
+	p.mu.Lock()
+	if existing, ok := p.forwardedPods[pod]; ok {
+		p.mu.Unlock()
+		return existing, nil
 	}
-	discard := ioutil.Discard
-	pf, err := portforward.New(dialer, ports, p.StopChan, readyChan, discard, discard)
+	p.mu.Unlock()
+
+	stopChan := make(chan struct{}, 1)
+	readyChan, doneChan, resolved, err := p.attemptForward(ctx, pod, mappings, stopChan)
 	if err != nil {
-		return errors.Wrap(err, "Could not port forward into pod")
+		return nil, err
 	}
 
-	go func() {
-		errChan <- pf.ForwardPorts()
-	}()
+	pfw := &podForward{pod: pod, mappings: resolved, stopChan: stopChan, readyChan: readyChan}
 
 	select {
-	case err = <-errChan:
-		return errors.Wrap(err, "Could not create port forward")
+	case err := <-doneChan:
+		return nil, errors.Wrapf(err, "Could not create port forward to pod %q", pod)
 	case <-readyChan:
-		return nil
 	}
 
+	p.mu.Lock()
+	p.forwardedPods[pod] = pfw
+	p.mu.Unlock()
+
+	go p.supervise(ctx, pfw, doneChan)
+
+	return pfw, nil
+}
+
+// attemptForward builds the dialer, opens the SPDY port forward for pod and
+// starts ForwardPorts in the background. doneChan receives ForwardPorts'
+// terminal error exactly once: nil on a clean Stop(), non-nil if the
+// underlying stream or API-server connection dropped. resolved is a copy of
+// mappings with every auto-assigned ListenPort (0) filled in with the port
+// getFreePort chose for this pod, so callers can record the real bind port.
+func (p *PortForward) attemptForward(ctx context.Context, pod string, mappings []PortMapping, stopChan chan struct{}) (readyChan chan struct{}, doneChan chan error, resolved []PortMapping, err error) {
+	dialer, err := p.dialerForPod(ctx, pod)
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "Could not create a dialer")
+	}
+
+	resolved = make([]PortMapping, len(mappings))
+	copy(resolved, mappings)
+
+	ports := make([]string, 0, len(resolved))
+	for i, m := range resolved {
+		listenPort := m.ListenPort
+		if listenPort == 0 {
+			listenPort, err = p.getFreePort()
+			if err != nil {
+				return nil, nil, nil, errors.Wrap(err, "Could not find a port to bind to")
+			}
+			resolved[i].ListenPort = listenPort
+		}
+		ports = append(ports, fmt.Sprintf("%d:%d", listenPort, m.DestinationPort))
+	}
+
+	readyChan = make(chan struct{}, 1)
+	doneChan = make(chan error, 1)
+
+	stdout, stderr := p.Stdout, p.Stderr
+	if stdout == nil {
+		stdout = ioutil.Discard
+	}
+	if stderr == nil {
+		stderr = ioutil.Discard
+	}
+
+	fw, err := portforward.New(dialer, ports, stopChan, readyChan, stdout, stderr)
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "Could not port forward into pod")
+	}
+
+	go func() {
+		doneChan <- fw.ForwardPorts()
+	}()
+
+	return readyChan, doneChan, resolved, nil
+}
+
+// supervise watches doneChan for pfw's forwarder exiting with an error and,
+// driven by ErrorHandler, re-resolves the pod, rebuilds the dialer and
+// restarts the tunnel with exponential backoff. It returns once the pod is
+// stopped cleanly, the handler says Abort, MaxRetries is exhausted, or ctx
+// is done.
+func (p *PortForward) supervise(ctx context.Context, pfw *podForward, doneChan chan error) {
+	maxRetries := p.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = defaultMaxRetries
+	}
+	maxBackoff := p.MaxBackoff
+	if maxBackoff == 0 {
+		maxBackoff = defaultMaxBackoff
+	}
+
+	backoff := defaultInitBackoff
+	retries := 0
+
+	giveUp := func() {
+		p.mu.Lock()
+		delete(p.forwardedPods, pfw.pod)
+		p.mu.Unlock()
+	}
+
+	for {
+		err, ok := <-doneChan
+		if !ok || err == nil {
+			giveUp()
+			return
+		}
+
+		action := Abort
+		if p.ErrorHandler != nil {
+			action = p.ErrorHandler(err)
+		}
+
+		if action == Abort {
+			log.Printf("Giving up on pod %q after error: %v", pfw.pod, err)
+			giveUp()
+			return
+		}
+
+		if action == Retry {
+			if retries >= maxRetries {
+				log.Printf("Giving up on pod %q after %d retries: %v", pfw.pod, retries, err)
+				giveUp()
+				return
+			}
+			retries++
+
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				giveUp()
+				return
+			}
+
+			backoff *= 2
+			if backoff > maxBackoff {
+				backoff = maxBackoff
+			}
+		}
+
+		pod := pfw.pod
+		if next, resolveErr := p.getPodName(ctx, pfw.pod); resolveErr == nil && next != "" {
+			pod = next
+		}
+
+		stopChan := make(chan struct{}, 1)
+		readyChan, newDoneChan, newMappings, attemptErr := p.attemptForward(ctx, pod, pfw.mappings, stopChan)
+		if attemptErr != nil {
+			log.Printf("Could not rebuild port forward for pod %q: %v", pod, attemptErr)
+			doneChan = errChanWith(attemptErr)
+			continue
+		}
+
+		select {
+		case attemptErr := <-newDoneChan:
+			log.Printf("Could not rebuild port forward for pod %q: %v", pod, attemptErr)
+			doneChan = errChanWith(attemptErr)
+			continue
+		case <-readyChan:
+		case <-ctx.Done():
+			giveUp()
+			return
+		}
+
+		p.mu.Lock()
+		if tracked, ok := p.forwardedPods[pfw.pod]; !ok || tracked != pfw {
+			// Stop() or stopPod() dropped this pod while it was reconnecting
+			// and closed its old stopChan; shut the rebuilt tunnel down too.
+			p.mu.Unlock()
+			close(stopChan)
+			return
+		}
+		if pod != pfw.pod {
+			delete(p.forwardedPods, pfw.pod)
+			p.forwardedPods[pod] = pfw
+		}
+		pfw.pod = pod
+		pfw.stopChan = stopChan
+		pfw.mappings = newMappings
+		p.mu.Unlock()
+
+		doneChan = newDoneChan
+		// The tunnel is back up: a later drop deserves the full retry budget
+		// again, not whatever was left over from this one.
+		retries = 0
+		backoff = defaultInitBackoff
+	}
+}
+
+// errChanWith returns a channel that immediately yields err, so callers that
+// loop on a doneChan can treat a failed reconnect attempt the same way as a
+// forwarder that started and then failed.
+func errChanWith(err error) chan error {
+	ch := make(chan error, 1)
+	ch <- err
+	return ch
+}
+
+// stopPod tears down the tunnel previously opened by startPod, if any.
+func (p *PortForward) stopPod(pod string) {
+	p.mu.Lock()
+	pfw, ok := p.forwardedPods[pod]
+	var stopChan chan struct{}
+	if ok {
+		delete(p.forwardedPods, pod)
+		stopChan = pfw.stopChan
+	}
+	p.mu.Unlock()
+
+	if ok {
+		close(stopChan)
+	}
+}
+
+// reconcile watches the namespace for pods matching Labels and keeps
+// forwardedPods in sync: terminated pods are torn down and newly Ready
+// replicas are forwarded.
+func (p *PortForward) reconcile(ctx context.Context, mappings []PortMapping) {
+	watcher, err := p.Clientset.CoreV1().Pods(p.Namespace).Watch(ctx, metav1.ListOptions{
+		LabelSelector: metav1.FormatLabelSelector(&p.Labels),
+	})
+	if err != nil {
+		log.Printf("Could not watch pods for selector %q: %v", metav1.FormatLabelSelector(&p.Labels), err)
+		return
+	}
+	defer watcher.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.StopChan:
+			return
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return
+			}
+
+			pod, ok := event.Object.(*v1.Pod)
+			if !ok {
+				continue
+			}
+
+			switch event.Type {
+			case watch.Deleted:
+				p.stopPod(pod.Name)
+			case watch.Added, watch.Modified:
+				if isPodReady(pod) {
+					if _, err := p.startPod(ctx, pod.Name, mappings); err != nil {
+						log.Printf("Could not start port forward for pod %q: %v", pod.Name, err)
+					}
+				} else {
+					p.stopPod(pod.Name)
+				}
+			}
+		}
+	}
+}
+
+// isPodReady reports whether pod has a true PodReady condition.
+func isPodReady(pod *v1.Pod) bool {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == v1.PodReady {
+			return cond.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// Ready reports whether at least one pod is currently being forwarded.
+func (p *PortForward) Ready() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.forwardedPods) > 0
+}
+
+// Addresses returns, for every currently forwarded pod, the local ports it
+// can be reached on.
+func (p *PortForward) Addresses() []PodAddress {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	addrs := make([]PodAddress, 0, len(p.forwardedPods))
+	for pod, pfw := range p.forwardedPods {
+		addrs = append(addrs, PodAddress{Pod: pod, Mappings: pfw.mappings})
+	}
+	return addrs
 }
 
 // Stop a port forward.
 func (p *PortForward) Stop() {
-	p.StopChan <- struct{}{}
+	p.mu.Lock()
+	// Read pfw.stopChan here, under the same lock supervise() uses to
+	// rebuild it on reconnect, so we always close the live channel instead
+	// of a stale one from before a reconnect.
+	stopChans := make([]chan struct{}, 0, len(p.forwardedPods))
+	for _, pfw := range p.forwardedPods {
+		stopChans = append(stopChans, pfw.stopChan)
+	}
+	p.forwardedPods = make(map[string]*podForward)
+	p.mu.Unlock()
+
+	for _, stopChan := range stopChans {
+		close(stopChan)
+	}
+
+	select {
+	case p.StopChan <- struct{}{}:
+	default:
+	}
+
+	if p.stopped != nil {
+		p.stopOnce.Do(func() { close(p.stopped) })
+	}
+
+	// Clear the (now closed) ready channel so a subsequent Start() allocates
+	// a fresh one instead of panicking on a second close.
+	p.ReadyChan = nil
 }
 
 // Returns the port that the port forward should listen on.
@@ -146,18 +759,21 @@ func (p *PortForward) getFreePort() (int, error) {
 	return port, nil
 }
 
-// Create an httpstream.Dialer for use with portforward.New
-func (p *PortForward) dialer(ctx context.Context) (httpstream.Dialer, error) {
-	pod, err := p.getPodName(ctx)
-	if err != nil {
-		return nil, errors.Wrap(err, "Could not get pod name")
+// dialerForPod builds an httpstream.Dialer targeting a specific pod, used
+// both for the single-pod path and for each replica found by
+// findPodsByLabels.
+func (p *PortForward) dialerForPod(ctx context.Context, pod string) (httpstream.Dialer, error) {
+	container := p.Container
+	if container == "" {
+		container = defaultContainer
 	}
+
 	url := p.Clientset.CoreV1().RESTClient().Post().
 		Resource("pods").
 		Namespace(p.Namespace).
 		Name(pod).
 		SubResource("portforward").VersionedParams(&v1.PodExecOptions{
-		Container: "nginx",
+		Container: container,
 		Stdin:     true,
 		Stdout:    true,
 		Stderr:    true,
@@ -173,70 +789,157 @@ func (p *PortForward) dialer(ctx context.Context) (httpstream.Dialer, error) {
 	return dialer, nil
 }
 
-// Gets the pod name to port forward to, if Name is set, Name is returned. Otherwise,
-// it will call findPodByLabels().
-func (p *PortForward) getPodName(ctx context.Context) (string, error) {
-	var err error
-	if p.Name == "" {
-		p.Name, err = p.findPodByLabels(ctx)
+// Gets the pod name to port forward to. If Name is set, Name is returned.
+// Otherwise, it calls findPodsByLabels() and round-robins between the
+// matches, so repeated calls spread load across replicas instead of always
+// picking the same one. current is the caller's own current pod, if any: a
+// candidate already forwarded under a different podForward is skipped, so a
+// reconnect can't steal a pod another podForward is using, but current
+// itself is never treated as in-use since it's about to be replaced.
+func (p *PortForward) getPodName(ctx context.Context, current string) (string, error) {
+	if p.Name != "" {
+		return p.Name, nil
+	}
+
+	pods, err := p.findPodsByLabels(ctx)
+	if err != nil {
+		return "", err
 	}
-	return p.Name, err
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for range pods {
+		pod := pods[p.rrIndex%len(pods)]
+		p.rrIndex++
+		if pod == current {
+			return pod, nil
+		}
+		if _, inUse := p.forwardedPods[pod]; !inUse {
+			return pod, nil
+		}
+	}
+
+	return current, nil
 }
 
-func (p *PortForward) findPodByLabels(ctx context.Context) (string, error) {
+// findPodsByLabels returns the pods matching Labels in Namespace, preferring
+// Ready pods (via the PodReady condition, not just status.phase=Running) and
+// falling back to every Running pod if none is Ready yet.
+func (p *PortForward) findPodsByLabels(ctx context.Context) ([]string, error) {
 	if len(p.Labels.MatchLabels) == 0 && len(p.Labels.MatchExpressions) == 0 {
-		return "", errors.New("No pod labels specified")
+		return nil, errors.New("No pod labels specified")
 	}
 
-	pods, err := p.Clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{
+	pods, err := p.Clientset.CoreV1().Pods(p.Namespace).List(ctx, metav1.ListOptions{
 		LabelSelector: metav1.FormatLabelSelector(&p.Labels),
 		FieldSelector: fields.OneTermEqualSelector("status.phase", string(v1.PodRunning)).String(),
 	})
 
 	if err != nil {
-		return "", errors.Wrap(err, "Listing pods in kubernetes")
+		return nil, errors.Wrap(err, "Listing pods in kubernetes")
 	}
 
 	formatted := metav1.FormatLabelSelector(&p.Labels)
 
 	if len(pods.Items) == 0 {
-		return "", errors.New(fmt.Sprintf("Could not find running pod for selector: labels \"%s\"", formatted))
+		return nil, errors.New(fmt.Sprintf("Could not find running pod for selector: labels \"%s\"", formatted))
 	}
 
-	if len(pods.Items) != 1 {
-		return "", errors.New(fmt.Sprintf("Ambiguous pod: found more than one pod for selector: labels \"%s\"", formatted))
+	names := make([]string, 0, len(pods.Items))
+	ready := make([]string, 0, len(pods.Items))
+	for _, pod := range pods.Items {
+		names = append(names, pod.ObjectMeta.Name)
+		if isPodReady(&pod) {
+			ready = append(ready, pod.ObjectMeta.Name)
+		}
 	}
 
-	return pods.Items[0].ObjectMeta.Name, nil
-}
-
-func (c *client) StartPortForward(ctx context.Context, args PortForwardArgs) (*PortForward, error) {
-	var err error
-	var Namespace, Pod string
-
-	var ListenPort, Port int
+	if len(ready) > 0 {
+		return ready, nil
+	}
 
-	labels := labelsFlags{}
+	return names, nil
+}
 
-	flag.Var(&labels, "label", "")
-	flag.IntVar(&ListenPort, "listen", ListenPort, "port to bind")
-	flag.IntVar(&Port, "Port", args.DestinationPort, "port to forward")
-	flag.StringVar(&Pod, "pod", args.Pod, "pod name")
-	flag.StringVar(&Namespace, "namespace", args.Instance, "namespacepod look for")
-	flag.Parse()
+// PortForwardOptions configures StartPortForward. It replaces passing flags
+// straight into the library: constructing one and wiring its fields from
+// flags is the caller's job (see cmd/plugin/rpaasv2), not this package's.
+type PortForwardOptions struct {
+	Pod           string
+	LabelSelector map[string]string
+	Namespace     string
+	LocalPort     int
+	RemotePort    int
+	Container     string
+	// Mappings, when set, forwards multiple local:remote port pairs instead
+	// of the single LocalPort/RemotePort pair.
+	Mappings []PortMapping
+	// Target, when its Kind is non-empty, resolves Pod/LabelSelector from a
+	// Service, Deployment or StatefulSet instead of having the caller supply
+	// them directly.
+	Target TargetRef
+	// ErrorHandler, when set, overrides the default always-Retry handler
+	// StartPortForward installs, so long-running sessions survive transient
+	// drops (e.g. a pod rollout) instead of exiting on the first one.
+	ErrorHandler ErrorHandler
+	// MaxRetries bounds how many times a pod is reconnected after an error
+	// before it is given up on. Zero means defaultMaxRetries.
+	MaxRetries int
+	// MaxBackoff caps the exponential backoff between reconnect attempts.
+	// Zero means defaultMaxBackoff.
+	MaxBackoff time.Duration
+	// Timeout, when non-zero, bounds how long the tunnel stays open; zero
+	// means it runs until ctx is canceled.
+	Timeout time.Duration
+	Stdout  io.Writer
+	Stderr  io.Writer
+	// ReadyCh, when set, is closed once the tunnel is up instead of the
+	// PortForward's own internal ReadyChan.
+	ReadyCh chan struct{}
+}
 
-	pf, err := NewPortForwarder(args.Pod, metav1.LabelSelector{MatchLabels: labels}, args.DestinationPort, args.Instance)
+// StartPortForward opens a tunnel described by opts and blocks until ctx is
+// done (or opts.Timeout elapses), tearing the tunnel down before returning.
+func (c *client) StartPortForward(ctx context.Context, opts PortForwardOptions) (*PortForward, error) {
+	pf, err := NewPortForwarder(opts.Pod, metav1.LabelSelector{MatchLabels: opts.LabelSelector}, opts.RemotePort, opts.Namespace)
 	if err != nil {
 		return pf, err
 	}
 
-	pf.ListenPort = args.ListenPort
-	err = pf.Start(context.TODO())
-	if err != nil {
-		log.Fatal("Error starting port forward:", err)
+	pf.ListenPort = opts.LocalPort
+	pf.Container = opts.Container
+	pf.Mappings = opts.Mappings
+	pf.Target = opts.Target
+	pf.MaxRetries = opts.MaxRetries
+	pf.MaxBackoff = opts.MaxBackoff
+	pf.ErrorHandler = opts.ErrorHandler
+	if pf.ErrorHandler == nil {
+		// A long-running CLI session should survive a dropped stream (e.g. a
+		// pod rollout) instead of exiting on the first one; MaxRetries/
+		// MaxBackoff still bound how long it keeps trying.
+		pf.ErrorHandler = func(error) Action { return Retry }
+	}
+	pf.Stdout = opts.Stdout
+	pf.Stderr = opts.Stderr
+	if opts.ReadyCh != nil {
+		pf.ReadyChan = opts.ReadyCh
+	}
+
+	fwCtx := ctx
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		fwCtx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	if err := pf.Start(fwCtx); err != nil {
+		return pf, errors.Wrap(err, "Error starting port forward")
 	}
 	log.Printf("Started tunnel on %d\n", pf.ListenPort)
-	time.Sleep(60 * time.Second)
 
-	return pf, err
+	<-fwCtx.Done()
+	pf.Stop()
+
+	return pf, nil
 }
@@ -0,0 +1,330 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestParsePortMappings(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    []PortMapping
+		wantErr bool
+	}{
+		{
+			name: "single bare port",
+			raw:  "8080",
+			want: []PortMapping{{ListenPort: 8080, DestinationPort: 8080}},
+		},
+		{
+			name: "single local:remote pair",
+			raw:  "8080:80",
+			want: []PortMapping{{ListenPort: 8080, DestinationPort: 80}},
+		},
+		{
+			name: "multiple comma-separated mappings",
+			raw:  "8080:80,8443:443",
+			want: []PortMapping{
+				{ListenPort: 8080, DestinationPort: 80},
+				{ListenPort: 8443, DestinationPort: 443},
+			},
+		},
+		{
+			name:    "empty string",
+			raw:     "",
+			wantErr: true,
+		},
+		{
+			name:    "invalid listen port",
+			raw:     "abc:80",
+			wantErr: true,
+		},
+		{
+			name:    "invalid destination port",
+			raw:     "8080:abc",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePortMappings(tt.raw)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got mappings %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("got %+v, want %+v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestResolveServiceTargetPort(t *testing.T) {
+	svc := &v1.Service{
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{Port: 80, TargetPort: intstr.FromInt(8080)},
+				{Port: 443, TargetPort: intstr.FromString("https")},
+			},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		servicePort int
+		want        int
+	}{
+		{name: "numeric targetPort is translated", servicePort: 80, want: 8080},
+		{name: "named targetPort falls back to the service port", servicePort: 443, want: 443},
+		{name: "no matching service port is returned unchanged", servicePort: 9999, want: 9999},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolveServiceTargetPort(svc, tt.servicePort); got != tt.want {
+				t.Fatalf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsPodReady(t *testing.T) {
+	readyPod := &v1.Pod{
+		Status: v1.PodStatus{
+			Conditions: []v1.PodCondition{
+				{Type: v1.PodReady, Status: v1.ConditionTrue},
+			},
+		},
+	}
+	notReadyPod := &v1.Pod{
+		Status: v1.PodStatus{
+			Conditions: []v1.PodCondition{
+				{Type: v1.PodReady, Status: v1.ConditionFalse},
+			},
+		},
+	}
+	noConditionPod := &v1.Pod{}
+
+	if !isPodReady(readyPod) {
+		t.Error("expected readyPod to be ready")
+	}
+	if isPodReady(notReadyPod) {
+		t.Error("expected notReadyPod to not be ready")
+	}
+	if isPodReady(noConditionPod) {
+		t.Error("expected a pod with no PodReady condition to not be ready")
+	}
+}
+
+func TestFindPodsByLabels_PrefersReadyPods(t *testing.T) {
+	labels := map[string]string{"app": "nginx"}
+
+	readyPod := podFixture("ready-pod", labels, true)
+	notReadyPod := podFixture("not-ready-pod", labels, false)
+
+	p := &PortForward{
+		Clientset: fake.NewSimpleClientset(readyPod, notReadyPod),
+		Namespace: "default",
+		Labels:    metav1.LabelSelector{MatchLabels: labels},
+	}
+
+	pods, err := p.findPodsByLabels(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(pods) != 1 || pods[0] != "ready-pod" {
+		t.Fatalf("expected only the ready pod to be returned, got %+v", pods)
+	}
+}
+
+func TestFindPodsByLabels_FallsBackToAllPodsWhenNoneReady(t *testing.T) {
+	labels := map[string]string{"app": "nginx"}
+	notReadyPod := podFixture("not-ready-pod", labels, false)
+
+	p := &PortForward{
+		Clientset: fake.NewSimpleClientset(notReadyPod),
+		Namespace: "default",
+		Labels:    metav1.LabelSelector{MatchLabels: labels},
+	}
+
+	pods, err := p.findPodsByLabels(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(pods) != 1 || pods[0] != "not-ready-pod" {
+		t.Fatalf("expected the not-ready pod as a fallback, got %+v", pods)
+	}
+}
+
+func TestFindPodsByLabels_NoMatches(t *testing.T) {
+	p := &PortForward{
+		Clientset: fake.NewSimpleClientset(),
+		Namespace: "default",
+		Labels:    metav1.LabelSelector{MatchLabels: map[string]string{"app": "nginx"}},
+	}
+
+	if _, err := p.findPodsByLabels(context.Background()); err == nil {
+		t.Fatal("expected an error when no pods match the selector")
+	}
+}
+
+func TestResolveTarget_Service(t *testing.T) {
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-svc", Namespace: "default"},
+		Spec: v1.ServiceSpec{
+			Selector: map[string]string{"app": "nginx"},
+			Ports: []v1.ServicePort{
+				{Port: 80, TargetPort: intstr.FromInt(8080)},
+			},
+		},
+	}
+
+	p := &PortForward{
+		Clientset:       fake.NewSimpleClientset(svc),
+		Namespace:       "default",
+		DestinationPort: 80,
+		Mappings:        []PortMapping{{ListenPort: 0, DestinationPort: 80}},
+		Target:          TargetRef{Kind: TargetKindService, Name: "my-svc"},
+	}
+
+	if err := p.resolveTarget(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.DestinationPort != 8080 {
+		t.Errorf("expected DestinationPort to be translated to 8080, got %d", p.DestinationPort)
+	}
+	if p.Mappings[0].DestinationPort != 8080 {
+		t.Errorf("expected Mappings[0].DestinationPort to be translated to 8080, got %d", p.Mappings[0].DestinationPort)
+	}
+	if p.Labels.MatchLabels["app"] != "nginx" {
+		t.Errorf("expected Labels to be resolved from the service selector, got %+v", p.Labels)
+	}
+}
+
+func TestResolveTarget_ServiceWithoutSelectorFails(t *testing.T) {
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-svc", Namespace: "default"},
+	}
+
+	p := &PortForward{
+		Clientset: fake.NewSimpleClientset(svc),
+		Namespace: "default",
+		Target:    TargetRef{Kind: TargetKindService, Name: "my-svc"},
+	}
+
+	if err := p.resolveTarget(context.Background()); err == nil {
+		t.Fatal("expected an error for a service with no selector")
+	}
+}
+
+func TestResolveTarget_Deployment(t *testing.T) {
+	dep := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-dep", Namespace: "default"},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "nginx"}},
+		},
+	}
+
+	p := &PortForward{
+		Clientset: fake.NewSimpleClientset(dep),
+		Namespace: "default",
+		Target:    TargetRef{Kind: TargetKindDeployment, Name: "my-dep"},
+	}
+
+	if err := p.resolveTarget(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Labels.MatchLabels["app"] != "nginx" {
+		t.Errorf("expected Labels to be resolved from the deployment selector, got %+v", p.Labels)
+	}
+}
+
+func TestResolveTarget_Pod(t *testing.T) {
+	p := &PortForward{Target: TargetRef{Kind: TargetKindPod, Name: "my-pod"}}
+
+	if err := p.resolveTarget(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Name != "my-pod" {
+		t.Errorf("expected Name to be set to %q, got %q", "my-pod", p.Name)
+	}
+}
+
+func TestPortMappings_FallsBackToDestinationAndListenPort(t *testing.T) {
+	p := &PortForward{DestinationPort: 80, ListenPort: 8080}
+
+	mappings, err := p.portMappings()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []PortMapping{{ListenPort: 8080, DestinationPort: 80}}
+	if len(mappings) != 1 || mappings[0] != want[0] {
+		t.Fatalf("got %+v, want %+v", mappings, want)
+	}
+}
+
+func TestPortMappings_PrefersExplicitMappings(t *testing.T) {
+	p := &PortForward{
+		DestinationPort: 80,
+		Mappings:        []PortMapping{{ListenPort: 9090, DestinationPort: 90}},
+	}
+
+	mappings, err := p.portMappings()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []PortMapping{{ListenPort: 9090, DestinationPort: 90}}
+	if len(mappings) != 1 || mappings[0] != want[0] {
+		t.Fatalf("got %+v, want %+v", mappings, want)
+	}
+}
+
+func TestStart_RejectsFixedListenPortForMultiplePods(t *testing.T) {
+	labels := map[string]string{"app": "nginx"}
+	podA := podFixture("pod-a", labels, true)
+	podB := podFixture("pod-b", labels, true)
+
+	p := &PortForward{
+		Clientset: fake.NewSimpleClientset(podA, podB),
+		Namespace: "default",
+		Labels:    metav1.LabelSelector{MatchLabels: labels},
+		Mappings:  []PortMapping{{ListenPort: 8080, DestinationPort: 80}},
+	}
+
+	err := p.Start(context.Background())
+	if err == nil {
+		t.Fatal("expected an error when a fixed listen port is combined with multiple matched pods")
+	}
+}
+
+func podFixture(name string, labels map[string]string, ready bool) *v1.Pod {
+	status := v1.ConditionFalse
+	if ready {
+		status = v1.ConditionTrue
+	}
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: labels},
+		Status: v1.PodStatus{
+			Phase:      v1.PodRunning,
+			Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: status}},
+		},
+	}
+}